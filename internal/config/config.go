@@ -0,0 +1,45 @@
+/* Copyright 2017 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package config holds configuration state that's threaded through
+// Gazelle's directory walk. Each language extension stashes its own
+// per-directory state under Exts, keyed by its own name.
+package config
+
+// Config holds the configuration for the directory currently being
+// processed. A directory's Config is derived from its parent's by
+// shallow-copying Exts (a new map, but the same extension pointers), so a
+// directive in one directory's BUILD file doesn't affect its ancestors or
+// siblings unless an extension clones its own entry before mutating it.
+type Config struct {
+	Exts map[string]interface{}
+}
+
+// New returns a Config with no directory-specific overrides.
+func New() *Config {
+	return &Config{Exts: make(map[string]interface{})}
+}
+
+// Clone returns a Config suitable for a subdirectory of the directory c
+// was configured for: a new Exts map pointing at the same extension
+// values c has. Extensions that mutate their own state in response to a
+// directive must copy their entry out of the shared map first.
+func (c *Config) Clone() *Config {
+	exts := make(map[string]interface{}, len(c.Exts))
+	for k, v := range c.Exts {
+		exts[k] = v
+	}
+	return &Config{Exts: exts}
+}
@@ -0,0 +1,122 @@
+/* Copyright 2017 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package label provides utilities for parsing and manipulating Bazel
+// labels. All Gazelle packages that need to refer to a Bazel target use
+// this package's Label type rather than passing label strings around.
+package label
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Label represents a label of a build target, e.g. @repo//pkg:name.
+type Label struct {
+	// Repo is the name of the external repository containing the target,
+	// without the leading "@". Repo is empty if the target is in the main
+	// repository.
+	Repo string
+
+	// Pkg is the slash-separated path to the package containing the
+	// target, relative to the repository root. Pkg is empty if the target
+	// is in the repository root.
+	Pkg string
+
+	// Name is the name of the target.
+	Name string
+}
+
+// New constructs a Label from its components.
+func New(repo, pkg, name string) Label {
+	return Label{Repo: repo, Pkg: pkg, Name: name}
+}
+
+// NoLabel is the zero value of Label. It's not a valid label and is used
+// to indicate the absence of a label, similar to a nil pointer.
+var NoLabel = Label{}
+
+// Parse parses a label string in the form
+// "[@repo]//pkg[:name]", "//pkg[:name]", or ":name", the same syntax
+// Bazel accepts on the command line and in BUILD files.
+func Parse(s string) (Label, error) {
+	origStr := s
+	var repo string
+	if strings.HasPrefix(s, "@") {
+		s = s[len("@"):]
+		end := strings.Index(s, "//")
+		if end < 0 {
+			return NoLabel, fmt.Errorf("label parse error: %q: repository name not followed by \"//\"", origStr)
+		}
+		repo = s[:end]
+		s = s[end:]
+	}
+
+	var pkg, name string
+	if strings.HasPrefix(s, "//") {
+		s = s[len("//"):]
+		if i := strings.Index(s, ":"); i >= 0 {
+			pkg, name = s[:i], s[i+1:]
+		} else {
+			// Bazel infers a package's default target name from the last
+			// slash-separated component of its path, e.g. //foo/bar is
+			// shorthand for //foo/bar:bar.
+			pkg = s
+			if i := strings.LastIndex(s, "/"); i >= 0 {
+				name = s[i+1:]
+			} else {
+				name = s
+			}
+		}
+	} else if strings.HasPrefix(s, ":") {
+		name = s[len(":"):]
+	} else {
+		return NoLabel, fmt.Errorf("label parse error: %q: must start with \"@\", \"//\", or \":\"", origStr)
+	}
+
+	if name == "" {
+		return NoLabel, fmt.Errorf("label parse error: %q: empty name", origStr)
+	}
+	return Label{Repo: repo, Pkg: pkg, Name: name}, nil
+}
+
+// String returns the canonical string representation of the label.
+func (l Label) String() string {
+	var b strings.Builder
+	if l.Repo != "" {
+		b.WriteString("@")
+		b.WriteString(l.Repo)
+	}
+	b.WriteString("//")
+	b.WriteString(l.Pkg)
+	b.WriteString(":")
+	b.WriteString(l.Name)
+	return b.String()
+}
+
+// Equal returns whether l and other refer to the same label.
+func (l Label) Equal(other Label) bool {
+	return l == other
+}
+
+// LabeledKind pairs a label with the kind of rule it refers to (e.g.
+// "go_library", "proto_library", "go_proto_library"). Some imports
+// legitimately resolve to more than one label depending on the kind of
+// rule asking, so known-imports tables that need to express that use
+// []LabeledKind rather than a single Label.
+type LabeledKind struct {
+	Label Label
+	Kind  string
+}
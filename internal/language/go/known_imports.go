@@ -0,0 +1,48 @@
+// Generated by internal/language/proto/gen/gen_known_imports.go
+// From internal/language/proto/gen/proto.csv
+
+package golang
+
+import (
+	"github.com/bazelbuild/bazel-gazelle/internal/label"
+	"github.com/bazelbuild/bazel-gazelle/internal/resolve"
+)
+
+var knownGoImports = map[string][]label.LabeledKind{
+
+	"github.com/golang/protobuf/ptypes/any": {
+
+		{Label: label.New("com_google_protobuf", "", "any_proto"), Kind: "proto_library"},
+		{Label: label.New("io_bazel_rules_go", "proto/wkt", "any_go_proto"), Kind: "go_proto_library"},
+	},
+	"github.com/golang/protobuf/ptypes/duration": {
+
+		{Label: label.New("com_google_protobuf", "", "duration_proto"), Kind: "proto_library"},
+		{Label: label.New("io_bazel_rules_go", "proto/wkt", "duration_go_proto"), Kind: "go_proto_library"},
+	},
+	"github.com/golang/protobuf/ptypes/timestamp": {
+
+		{Label: label.New("com_google_protobuf", "", "timestamp_proto"), Kind: "proto_library"},
+		{Label: label.New("io_bazel_rules_go", "proto/wkt", "timestamp_go_proto"), Kind: "go_proto_library"},
+	},
+}
+
+var knownGoImportsByLabel = map[label.Label]string{
+
+	label.New("com_google_protobuf", "", "any_proto"):                 "github.com/golang/protobuf/ptypes/any",
+	label.New("io_bazel_rules_go", "proto/wkt", "any_go_proto"):       "github.com/golang/protobuf/ptypes/any",
+	label.New("com_google_protobuf", "", "duration_proto"):            "github.com/golang/protobuf/ptypes/duration",
+	label.New("io_bazel_rules_go", "proto/wkt", "duration_go_proto"):  "github.com/golang/protobuf/ptypes/duration",
+	label.New("com_google_protobuf", "", "timestamp_proto"):           "github.com/golang/protobuf/ptypes/timestamp",
+	label.New("io_bazel_rules_go", "proto/wkt", "timestamp_go_proto"): "github.com/golang/protobuf/ptypes/timestamp",
+}
+
+// RegisterknownGoImports seeds idx with knownGoImports, so resolve.Resolve can find
+// these baked-in bindings without special-casing this map.
+func RegisterknownGoImports(idx *resolve.RuleIndex) {
+	for imp, kinds := range knownGoImports {
+		for _, lk := range kinds {
+			idx.AddStatic(resolve.GoLang, imp, lk.Kind, lk.Label)
+		}
+	}
+}
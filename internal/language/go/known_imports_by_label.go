@@ -0,0 +1,27 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import "github.com/bazelbuild/bazel-gazelle/internal/label"
+
+// ImportPathByLabel returns the canonical go import path that
+// gen_known_imports.go recorded for l, if any. Consumers such as a
+// gopackagesdriver can use this to recover a PackageID's ImportPath from a
+// Bazel label without re-parsing proto.csv at runtime.
+func ImportPathByLabel(l label.Label) (string, bool) {
+	imp, ok := knownGoImportsByLabel[l]
+	return imp, ok
+}
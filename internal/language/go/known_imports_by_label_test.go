@@ -0,0 +1,37 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"testing"
+
+	"github.com/bazelbuild/bazel-gazelle/internal/label"
+)
+
+func TestImportPathByLabel(t *testing.T) {
+	lbl := label.New("com_google_protobuf", "", "any_proto")
+	imp, ok := ImportPathByLabel(lbl)
+	if !ok || imp != "github.com/golang/protobuf/ptypes/any" {
+		t.Errorf("ImportPathByLabel(%s) = %q, %v; want \"github.com/golang/protobuf/ptypes/any\", true", lbl, imp, ok)
+	}
+}
+
+func TestImportPathByLabelMiss(t *testing.T) {
+	lbl := label.New("", "unknown", "unknown_proto")
+	if _, ok := ImportPathByLabel(lbl); ok {
+		t.Errorf("ImportPathByLabel(%s) = _, true; want false for a label knownGoImportsByLabel doesn't know about", lbl)
+	}
+}
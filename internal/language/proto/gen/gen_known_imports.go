@@ -13,9 +13,32 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// gen_known_imports generates a .go file that with a map from either proto or
-// go import strings to Bazel label strings. The imports for all languages
-// are stored in a proto.csv file.
+// gen_known_imports generates a .go file with one or more maps from either
+// proto or go import strings to Bazel labels. The imports for all
+// languages are stored in a single proto.csv file; each -output flag picks
+// out a (keyColumn, valueColumn) pair from that file and names the map that
+// should be generated for it. All maps are written to the same output file,
+// so e.g. the proto->label, proto->go import, and go import->label tables
+// can be kept in sync from one source without three separate genrules.
+//
+// Alongside each {{.Var}} map, a {{.Var}}ByLabel map is also generated,
+// mapping back from a Bazel label to the import string that produced it.
+// This lets driver-style consumers (e.g. a gopackagesdriver) go from a
+// go_proto_library label to its canonical import path without re-parsing
+// proto.csv at runtime.
+//
+// Each output also gets a Register{{.Var}} function, which seeds a
+// resolve.RuleIndex with these baked-in bindings, so the index is the only
+// thing resolvers need to consult (see internal/resolve). The generated
+// file does not call it itself.
+//
+// An import may legitimately resolve to more than one label depending on
+// the kind of rule asking (a .proto file has both a proto_library and a
+// go_proto_library, and well-known types resolve differently under
+// @com_google_protobuf than under @io_bazel_rules_go). So {{.Var}}'s value
+// type is []label.LabeledKind, one entry per (label, rule kind) pair, and
+// proto.csv's optional kind column (selected with -output's kind=N) tags
+// each row with the rule kind its label belongs to.
 
 package main
 
@@ -29,6 +52,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"text/template"
 
 	"github.com/bazelbuild/bazel-gazelle/internal/label"
@@ -42,23 +67,149 @@ var knownImportsTpl = template.Must(template.New("known_imports.go").Parse(`
 
 package {{.Package}}
 
-import "github.com/bazelbuild/bazel-gazelle/internal/label"
+import (
+	"github.com/bazelbuild/bazel-gazelle/internal/label"
+	"github.com/bazelbuild/bazel-gazelle/internal/resolve"
+)
+{{range .Outputs}}
+var {{.Var}} = map[string][]label.LabeledKind{
+{{range .Groups}}
+	{{printf "%q" .Import}}: {
+	{{range .Entries}}
+		{{printf "{Label: label.New(%q, %q, %q), Kind: %q}" .Label.Repo .Label.Pkg .Label.Name .Kind}},
+	{{- end}}
+	},
+{{- end}}
+}
 
-var {{.Var}} = map[string]label.Label{
-{{range .Bindings}}
-	{{printf "%q: label.New(%q, %q, %q)" .Import .Label.Repo .Label.Pkg .Label.Name}},
+var {{.Var}}ByLabel = map[label.Label]string{
+{{range .ByLabel}}
+	{{printf "label.New(%q, %q, %q): %q" .Label.Repo .Label.Pkg .Label.Name .Import}},
 {{- end}}
 }
-`))
+
+// Register{{.Var}} seeds idx with {{.Var}}, so resolve.Resolve can find
+// these baked-in bindings without special-casing this map.
+func Register{{.Var}}(idx *resolve.RuleIndex) {
+	for imp, kinds := range {{.Var}} {
+		for _, lk := range kinds {
+			idx.AddStatic({{.LangConst}}, imp, lk.Kind, lk.Label)
+		}
+	}
+}
+{{end}}`))
 
 type data struct {
-	ProtoCsv, Package, Var string
-	Bindings               []binding
+	ProtoCsv, Package string
+	Outputs           []*output
+}
+
+// output describes one generated map: which columns of proto.csv it reads
+// and what the resulting var should be called.
+type output struct {
+	Package     string
+	Var         string
+	KeyColumn   int
+	ValueColumn int
+	// KindColumn selects the column holding the rule-kind qualifier for
+	// each row (e.g. "proto_library", "go_proto_library", "go_library").
+	// -1 means proto.csv has no kind column for this output, and every
+	// binding gets the empty kind.
+	KindColumn int
+	// Lang is "go" or "proto", naming the resolve.Language the generated
+	// Register function should seed its bindings under.
+	Lang     string
+	Bindings []binding
+	Groups   []importGroup
+	ByLabel  []binding
 }
 
+// importGroup collects every (label, kind) binding recorded for a single
+// import string, in the order they were first seen in proto.csv.
+type importGroup struct {
+	Import  string
+	Entries []binding
+}
+
+// LangConst returns the resolve.Language constant expression for out.Lang,
+// for use by the generated Register{{.Var}} function.
+func (out *output) LangConst() (string, error) {
+	switch out.Lang {
+	case "go":
+		return "resolve.GoLang", nil
+	case "proto":
+		return "resolve.ProtoLang", nil
+	default:
+		return "", fmt.Errorf("-output: lang must be \"go\" or \"proto\", got %q", out.Lang)
+	}
+}
+
+// binding is this generator's own copy of a (import, kind, label) row; its
+// kind and label fields are the same label.LabeledKind the generated
+// {{.Var}} map's value type uses, so the two stay in lock-step.
 type binding struct {
 	Import string
-	Label  label.Label
+	label.LabeledKind
+}
+
+// outputFlags collects repeated -output flags into a list of output specs.
+type outputFlags []*output
+
+func (o *outputFlags) String() string {
+	if o == nil {
+		return ""
+	}
+	parts := make([]string, len(*o))
+	for i, out := range *o {
+		parts[i] = fmt.Sprintf("package=%s,var=%s,key=%d,value=%d,kind=%d,lang=%s", out.Package, out.Var, out.KeyColumn, out.ValueColumn, out.KindColumn, out.Lang)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Set parses a spec of the form
+// "package=pkg,var=name,key=N,value=N,kind=N,lang=go" and appends it to the
+// list of outputs. kind is optional; omit it for CSVs with no rule-kind
+// column.
+func (o *outputFlags) Set(s string) error {
+	out := &output{KeyColumn: 0, ValueColumn: 1, KindColumn: -1}
+	for _, field := range strings.Split(s, ",") {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("-output: invalid field %q (want key=value)", field)
+		}
+		key, value := kv[0], kv[1]
+		var err error
+		switch key {
+		case "package":
+			out.Package = value
+		case "var":
+			out.Var = value
+		case "key":
+			out.KeyColumn, err = strconv.Atoi(value)
+		case "value":
+			out.ValueColumn, err = strconv.Atoi(value)
+		case "kind":
+			out.KindColumn, err = strconv.Atoi(value)
+		case "lang":
+			out.Lang = value
+		default:
+			return fmt.Errorf("-output: unknown field %q", key)
+		}
+		if err != nil {
+			return fmt.Errorf("-output: %v", err)
+		}
+	}
+	if out.Package == "" {
+		return fmt.Errorf("-output: package not set in %q", s)
+	}
+	if out.Var == "" {
+		return fmt.Errorf("-output: var not set in %q", s)
+	}
+	if out.Lang != "go" && out.Lang != "proto" {
+		return fmt.Errorf("-output: lang must be \"go\" or \"proto\", got %q in %q", out.Lang, s)
+	}
+	*o = append(*o, out)
+	return nil
 }
 
 func main() {
@@ -71,14 +222,11 @@ func main() {
 
 func run(args []string) (err error) {
 	fs := flag.NewFlagSet(progName, flag.ExitOnError)
-	var protoCsvPath, knownImportsPath, package_, var_ string
-	var keyColumn, valueColumn int
+	var protoCsvPath, knownImportsPath string
+	var outputs outputFlags
 	fs.StringVar(&protoCsvPath, "proto_csv", "", "path to proto.csv input file")
 	fs.StringVar(&knownImportsPath, "known_imports", "", "path to known_imports.go output file")
-	fs.StringVar(&package_, "package", "", "package name in generated file")
-	fs.StringVar(&var_, "var", "", "var name in generated file")
-	fs.IntVar(&keyColumn, "key", 0, "key column number")
-	fs.IntVar(&valueColumn, "value", 1, "value column number")
+	fs.Var(&outputs, "output", "package=pkg,var=name,key=N,value=N,kind=N,lang=go|proto; kind is optional; may be repeated to emit several maps into one file")
 	fs.Parse(args)
 	if protoCsvPath == "" {
 		return fmt.Errorf("-proto_csv not set")
@@ -86,11 +234,13 @@ func run(args []string) (err error) {
 	if knownImportsPath == "" {
 		return fmt.Errorf("-known_imports not set")
 	}
-	if package_ == "" {
-		return fmt.Errorf("-package not set")
+	if len(outputs) == 0 {
+		return fmt.Errorf("-output not set (need at least one)")
 	}
-	if var_ == "" {
-		return fmt.Errorf("-var not set")
+	for _, out := range outputs {
+		if out.Package != outputs[0].Package {
+			return fmt.Errorf("-output: all outputs must share the same package, got %q and %q", outputs[0].Package, out.Package)
+		}
 	}
 
 	protoCsvFile, err := os.Open(protoCsvPath)
@@ -105,26 +255,59 @@ func run(args []string) (err error) {
 	if err != nil {
 		return err
 	}
+
 	data := data{
 		ProtoCsv: protoCsvPath,
-		Package:  package_,
-		Var:      var_,
+		Package:  outputs[0].Package,
+		Outputs:  outputs,
 	}
-	seen := make(map[string]label.Label)
-	for _, rec := range records {
-		imp := rec[keyColumn]
-		lbl, err := label.Parse(rec[valueColumn])
-		if err != nil {
-			return err
+	for _, out := range outputs {
+		type importKind struct {
+			Import, Kind string
+		}
+		seen := make(map[importKind]label.Label)
+		groupIndex := make(map[string]int)
+		for _, rec := range records {
+			imp := rec[out.KeyColumn]
+			lbl, err := label.Parse(rec[out.ValueColumn])
+			if err != nil {
+				return err
+			}
+			kind := ""
+			if out.KindColumn >= 0 {
+				kind = rec[out.KindColumn]
+			}
+			ik := importKind{imp, kind}
+			if seenLabel, ok := seen[ik]; ok {
+				if !seenLabel.Equal(lbl) {
+					return fmt.Errorf("for key %s (kind %q), multiple values (%s and %s)", imp, kind, seenLabel, lbl)
+				}
+				continue
+			}
+			seen[ik] = lbl
+			b := binding{Import: imp, LabeledKind: label.LabeledKind{Label: lbl, Kind: kind}}
+			out.Bindings = append(out.Bindings, b)
+
+			i, ok := groupIndex[imp]
+			if !ok {
+				i = len(out.Groups)
+				groupIndex[imp] = i
+				out.Groups = append(out.Groups, importGroup{Import: imp})
+			}
+			out.Groups[i].Entries = append(out.Groups[i].Entries, b)
 		}
-		if seenLabel, ok := seen[imp]; ok {
-			if !seenLabel.Equal(lbl) {
-				return fmt.Errorf("for key %s, multiple values (%s and %s)", imp, seenLabel, lbl)
+
+		seenByLabel := make(map[label.Label]string)
+		for _, b := range out.Bindings {
+			if seenImp, ok := seenByLabel[b.Label]; ok {
+				if seenImp != b.Import {
+					log.Printf("warning: label %s maps to multiple imports (%s and %s); keeping %s", b.Label, seenImp, b.Import, seenImp)
+				}
+				continue
 			}
-			continue
+			seenByLabel[b.Label] = b.Import
+			out.ByLabel = append(out.ByLabel, b)
 		}
-		seen[imp] = lbl
-		data.Bindings = append(data.Bindings, binding{imp, lbl})
 	}
 
 	knownImportsBuf := &bytes.Buffer{}
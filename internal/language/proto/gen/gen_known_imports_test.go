@@ -0,0 +1,194 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputFlagsSet(t *testing.T) {
+	var outputs outputFlags
+	if err := outputs.Set("package=proto,var=knownProtoImports,key=0,value=1,kind=3,lang=proto"); err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs) != 1 {
+		t.Fatalf("got %d outputs, want 1", len(outputs))
+	}
+	out := outputs[0]
+	if out.Package != "proto" || out.Var != "knownProtoImports" || out.KeyColumn != 0 || out.ValueColumn != 1 || out.KindColumn != 3 || out.Lang != "proto" {
+		t.Errorf("Set parsed %+v incorrectly", out)
+	}
+}
+
+func TestOutputFlagsSetKindDefaultsToUnset(t *testing.T) {
+	var outputs outputFlags
+	if err := outputs.Set("package=golang,var=knownGoImports,key=0,value=1,lang=go"); err != nil {
+		t.Fatal(err)
+	}
+	if got := outputs[0].KindColumn; got != -1 {
+		t.Errorf("KindColumn = %d, want -1 when kind= is omitted", got)
+	}
+}
+
+func TestOutputFlagsSetRejectsBadLang(t *testing.T) {
+	var outputs outputFlags
+	if err := outputs.Set("package=proto,var=x,lang=python"); err == nil {
+		t.Error("Set accepted an unsupported lang")
+	}
+}
+
+func writeTempCSV(t *testing.T, contents string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "gen_known_imports_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "proto.csv")
+	if err := ioutil.WriteFile(path, []byte(contents), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunGeneratesPerKindBindings(t *testing.T) {
+	csvPath := writeTempCSV(t, `google/protobuf/any.proto,@com_google_protobuf//:any_proto,example,proto_library
+google/protobuf/any.proto,@io_bazel_rules_go//proto/wkt:any_go_proto,example,go_proto_library
+`)
+	outPath := filepath.Join(filepath.Dir(csvPath), "known_imports.go")
+
+	err := run([]string{
+		"-proto_csv", csvPath,
+		"-known_imports", outPath,
+		"-output", "package=proto,var=knownProtoImports,key=0,value=1,kind=3,lang=proto",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := ioutil.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(generated)
+	for _, want := range []string{
+		`Label: label.New("com_google_protobuf", "", "any_proto"), Kind: "proto_library"`,
+		`Label: label.New("io_bazel_rules_go", "proto/wkt", "any_go_proto"), Kind: "go_proto_library"`,
+		"idx.AddStatic(resolve.ProtoLang, imp, lk.Kind, lk.Label)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("generated file missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRunRejectsConflictingBindingForSameKind(t *testing.T) {
+	csvPath := writeTempCSV(t, `google/protobuf/any.proto,@com_google_protobuf//:any_proto,example,proto_library
+google/protobuf/any.proto,@other_repo//:any_proto,example,proto_library
+`)
+	outPath := filepath.Join(filepath.Dir(csvPath), "known_imports.go")
+
+	err := run([]string{
+		"-proto_csv", csvPath,
+		"-known_imports", outPath,
+		"-output", "package=proto,var=knownProtoImports,key=0,value=1,kind=3,lang=proto",
+	})
+	if err == nil {
+		t.Fatal("run did not report an error for two different labels under the same (import, kind)")
+	}
+}
+
+func TestRunGeneratesByLabelMap(t *testing.T) {
+	csvPath := writeTempCSV(t, `google/protobuf/any.proto,@com_google_protobuf//:any_proto,example,proto_library
+`)
+	outPath := filepath.Join(filepath.Dir(csvPath), "known_imports.go")
+
+	err := run([]string{
+		"-proto_csv", csvPath,
+		"-known_imports", outPath,
+		"-output", "package=proto,var=knownProtoImports,key=0,value=1,kind=3,lang=proto",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := ioutil.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(generated)
+	for _, want := range []string{
+		"var knownProtoImportsByLabel = map[label.Label]string{",
+		`label.New("com_google_protobuf", "", "any_proto"): "google/protobuf/any.proto"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("generated file missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRunWarnsWhenLabelMapsToMultipleImports(t *testing.T) {
+	csvPath := writeTempCSV(t, `google/protobuf/any.proto,@com_google_protobuf//:any_proto,example,proto_library
+google/protobuf/any2.proto,@com_google_protobuf//:any_proto,example,proto_library
+`)
+	outPath := filepath.Join(filepath.Dir(csvPath), "known_imports.go")
+
+	var logBuf bytes.Buffer
+	log.SetOutput(&logBuf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	err := run([]string{
+		"-proto_csv", csvPath,
+		"-known_imports", outPath,
+		"-output", "package=proto,var=knownProtoImports,key=0,value=1,kind=3,lang=proto",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logBuf.String(), "maps to multiple imports") {
+		t.Errorf("run did not warn about a label mapping to multiple imports; log output: %q", logBuf.String())
+	}
+
+	generated, err := ioutil.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// First-wins: the label should map back to the import it was seen with
+	// first in proto.csv, not the later duplicate.
+	if want := `label.New("com_google_protobuf", "", "any_proto"): "google/protobuf/any.proto"`; !strings.Contains(string(generated), want) {
+		t.Errorf("generated file does not keep the first-seen import for the colliding label; want %q in:\n%s", want, generated)
+	}
+}
+
+func TestRunAllowsSameImportDifferentKinds(t *testing.T) {
+	csvPath := writeTempCSV(t, `google/protobuf/any.proto,@com_google_protobuf//:any_proto,example,proto_library
+google/protobuf/any.proto,@io_bazel_rules_go//proto/wkt:any_go_proto,example,go_proto_library
+`)
+	outPath := filepath.Join(filepath.Dir(csvPath), "known_imports.go")
+
+	err := run([]string{
+		"-proto_csv", csvPath,
+		"-known_imports", outPath,
+		"-output", "package=proto,var=knownProtoImports,key=0,value=1,kind=3,lang=proto",
+	})
+	if err != nil {
+		t.Fatalf("run reported an error for the same import under two different kinds: %v", err)
+	}
+}
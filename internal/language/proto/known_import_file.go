@@ -0,0 +1,197 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proto
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bazelbuild/bazel-gazelle/internal/config"
+	"github.com/bazelbuild/bazel-gazelle/internal/label"
+	"github.com/bazelbuild/bazel-gazelle/internal/resolve"
+	"github.com/bazelbuild/bazel-gazelle/internal/rule"
+)
+
+// knownImportFileDirective is the name of the `# gazelle:known_import_file`
+// directive. Its value is a path, relative to the repo root, to a CSV or
+// JSON file of extra import -> label.Label bindings, so users can teach
+// Gazelle about additional proto or go imports without patching and
+// rebuilding it. resolveKnownImport consults entries loaded this way before
+// the knownProtoImports/knownGoProtoImports maps baked in by
+// gen_known_imports.go, but after the RuleIndex it is given, so a static
+// entry that a Register function seeded into that index wins over the
+// overlay. knownGoImports is not consulted here.
+const knownImportFileDirective = "known_import_file"
+
+// protoConfig holds proto-language config accumulated from directives.
+type protoConfig struct {
+	// knownImportOverlay holds entries loaded from knownImportFileDirective,
+	// keyed the same way knownProtoImports/knownGoProtoImports are: an
+	// import can carry more than one (label, kind) binding. A nil map means
+	// no overlay was configured.
+	knownImportOverlay map[string][]label.LabeledKind
+}
+
+func getProtoConfig(c *config.Config) *protoConfig {
+	pc, ok := c.Exts["proto"].(*protoConfig)
+	if !ok {
+		pc = &protoConfig{}
+	}
+	return pc
+}
+
+// setKnownImportFile loads path and stores its bindings in c's proto config,
+// overwriting any overlay loaded by an ancestor directory's directive. c's
+// existing *protoConfig (if any) is shared with c's parent and siblings
+// cloned from the same parent, so it's copied before the overlay is set;
+// mutating it in place would leak this directory's directive to them.
+func setKnownImportFile(c *config.Config, repoRoot, path string) error {
+	overlay, err := loadKnownImportOverlay(filepath.Join(repoRoot, path))
+	if err != nil {
+		return err
+	}
+	pcCopy := *getProtoConfig(c)
+	pcCopy.knownImportOverlay = overlay
+	c.Exts["proto"] = &pcCopy
+	return nil
+}
+
+// applyKnownImportFileDirectives scans directives for knownImportFileDirective
+// and loads the overlay it names, if any. Configure should call this for
+// every BUILD file it visits, the same way it handles other `# gazelle:`
+// directives.
+func applyKnownImportFileDirectives(c *config.Config, repoRoot string, directives []rule.Directive) error {
+	for _, d := range directives {
+		if d.Key != knownImportFileDirective {
+			continue
+		}
+		if err := setKnownImportFile(c, repoRoot, d.Value); err != nil {
+			return fmt.Errorf("%s: %v", knownImportFileDirective, err)
+		}
+	}
+	return nil
+}
+
+// overlayJSONEntry is one binding for an import in the JSON overlay format:
+// {"google/protobuf/any.proto": [{"label": "...", "kind": "proto_library"}]}.
+// Kind may be omitted for an overlay file that only ever binds one label per
+// import.
+type overlayJSONEntry struct {
+	Label string `json:"label"`
+	Kind  string `json:"kind"`
+}
+
+// loadKnownImportOverlay reads extra import -> []label.LabeledKind bindings
+// from a user-provided file. CSV files use the same 4-column schema that
+// gen_known_imports.go consumes (import, label, an unused go-import column,
+// and kind), so the bundled proto.csv and a user override file are
+// interchangeable, including proto.csv's one-row-per-kind convention for
+// imports like the well-known types that resolve differently per rule kind.
+// JSON files are a {"import": [{"label": ..., "kind": ...}, ...]} object,
+// for tools that emit overlays programmatically.
+func loadKnownImportOverlay(path string) (map[string][]label.LabeledKind, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	overlay := make(map[string][]label.LabeledKind)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		var raw map[string][]overlayJSONEntry
+		if err := json.NewDecoder(f).Decode(&raw); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		for imp, entries := range raw {
+			for _, e := range entries {
+				lbl, err := label.Parse(e.Label)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %s: %v", path, imp, err)
+				}
+				overlay[imp] = append(overlay[imp], label.LabeledKind{Label: lbl, Kind: e.Kind})
+			}
+		}
+	case ".csv", "":
+		r := csv.NewReader(bufio.NewReader(f))
+		r.Comment = '#'
+		r.FieldsPerRecord = 4
+		records, err := r.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		for _, rec := range records {
+			imp, kind := rec[0], rec[3]
+			lbl, err := label.Parse(rec[1])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %v", path, imp, err)
+			}
+			overlay[imp] = append(overlay[imp], label.LabeledKind{Label: lbl, Kind: kind})
+		}
+	default:
+		return nil, fmt.Errorf("%s: unknown known_import_file format %q (want .csv or .json)", path, ext)
+	}
+	return overlay, nil
+}
+
+// resolveKnownImport resolves imp, as requested by a rule of the given
+// kind (e.g. "proto_library" or "go_proto_library"), in idx, against
+// resolve.Resolve's standard order (a `# gazelle:resolve` override, then
+// idx's workspace and static entries), falling back to the
+// known_import_file overlay and finally the generated knownProtoImports
+// and knownGoProtoImports maps.
+//
+// This is the proto language's resolution entry point; nothing in this
+// tree calls it yet, since the rule-generation driver that would call it
+// for each proto_library/go_proto_library's discovered imports doesn't
+// exist in this snapshot. It's wired up this far so that driver has a
+// single function to call once it exists.
+func resolveKnownImport(c *config.Config, idx *resolve.RuleIndex, imp, kind string) (label.Label, bool) {
+	if lbl, ok := resolve.Resolve(c, idx, resolve.ProtoLang, imp, kind); ok {
+		return lbl, true
+	}
+	pc := getProtoConfig(c)
+	if lbl, ok := findLabeledKind(pc.knownImportOverlay[imp], kind); ok {
+		return lbl, true
+	}
+	if lbl, ok := findLabeledKind(knownProtoImports[imp], kind); ok {
+		return lbl, true
+	}
+	return findLabeledKind(knownGoProtoImports[imp], kind)
+}
+
+// findLabeledKind returns the label tagged with kind among lks, if any. An
+// lks entry with an empty Kind is a wildcard: it matches any requested
+// kind, so an overlay row with the kind column omitted can still resolve a
+// lookup made with a concrete kind like "proto_library".
+func findLabeledKind(lks []label.LabeledKind, kind string) (label.Label, bool) {
+	var wildcard label.Label
+	haveWildcard := false
+	for _, lk := range lks {
+		if lk.Kind == kind {
+			return lk.Label, true
+		}
+		if lk.Kind == "" {
+			wildcard, haveWildcard = lk.Label, true
+		}
+	}
+	return wildcard, haveWildcard
+}
@@ -0,0 +1,168 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proto
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bazelbuild/bazel-gazelle/internal/config"
+	"github.com/bazelbuild/bazel-gazelle/internal/label"
+	"github.com/bazelbuild/bazel-gazelle/internal/resolve"
+)
+
+func writeTempFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "known_import_file_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(contents), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadKnownImportOverlayCSV(t *testing.T) {
+	path := writeTempFile(t, "overlay.csv", `# comment line
+example.com/foo.proto,@foo//:foo_proto,example.com/foo,proto_library
+example.com/foo.proto,@foo//:foo_go_proto,example.com/foo,go_proto_library
+`)
+	overlay, err := loadKnownImportOverlay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lks := overlay["example.com/foo.proto"]
+	if len(lks) != 2 {
+		t.Fatalf("got %d bindings for example.com/foo.proto, want 2: %v", len(lks), lks)
+	}
+	if lbl, ok := findLabeledKind(lks, "proto_library"); !ok || lbl.String() != "@foo//:foo_proto" {
+		t.Errorf("proto_library binding = %s, %v; want @foo//:foo_proto, true", lbl, ok)
+	}
+	if lbl, ok := findLabeledKind(lks, "go_proto_library"); !ok || lbl.String() != "@foo//:foo_go_proto" {
+		t.Errorf("go_proto_library binding = %s, %v; want @foo//:foo_go_proto, true", lbl, ok)
+	}
+}
+
+func TestLoadKnownImportOverlayJSON(t *testing.T) {
+	path := writeTempFile(t, "overlay.json", `{
+  "example.com/foo.proto": [
+    {"label": "@foo//:foo_proto", "kind": "proto_library"},
+    {"label": "@foo//:foo_go_proto", "kind": "go_proto_library"}
+  ]
+}`)
+	overlay, err := loadKnownImportOverlay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lks := overlay["example.com/foo.proto"]
+	if len(lks) != 2 {
+		t.Fatalf("got %d bindings for example.com/foo.proto, want 2: %v", len(lks), lks)
+	}
+	if lbl, ok := findLabeledKind(lks, "proto_library"); !ok || lbl.String() != "@foo//:foo_proto" {
+		t.Errorf("proto_library binding = %s, %v; want @foo//:foo_proto, true", lbl, ok)
+	}
+	if lbl, ok := findLabeledKind(lks, "go_proto_library"); !ok || lbl.String() != "@foo//:foo_go_proto" {
+		t.Errorf("go_proto_library binding = %s, %v; want @foo//:foo_go_proto, true", lbl, ok)
+	}
+}
+
+func TestFindLabeledKindMiss(t *testing.T) {
+	lks := []label.LabeledKind{{Label: label.New("", "", "foo"), Kind: "proto_library"}}
+	if _, ok := findLabeledKind(lks, "go_proto_library"); ok {
+		t.Error("findLabeledKind found a binding for a kind that wasn't present")
+	}
+}
+
+func TestFindLabeledKindWildcard(t *testing.T) {
+	wantLbl := label.New("", "", "foo")
+	lks := []label.LabeledKind{{Label: wantLbl, Kind: ""}}
+	lbl, ok := findLabeledKind(lks, "proto_library")
+	if !ok {
+		t.Fatal("findLabeledKind did not match a concrete kind against a wildcard (empty-kind) entry")
+	}
+	if !lbl.Equal(wantLbl) {
+		t.Errorf("findLabeledKind returned %s, want %s", lbl, wantLbl)
+	}
+}
+
+func TestLoadKnownImportOverlayCSVOmittedKind(t *testing.T) {
+	path := writeTempFile(t, "overlay.csv", `example.com/foo.proto,@foo//:foo_proto,example.com/foo,
+`)
+	overlay, err := loadKnownImportOverlay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lbl, ok := findLabeledKind(overlay["example.com/foo.proto"], "proto_library")
+	if !ok {
+		t.Fatal("an overlay row with an omitted kind column did not resolve a lookup made with a concrete kind")
+	}
+	if want := "@foo//:foo_proto"; lbl.String() != want {
+		t.Errorf("findLabeledKind returned %s, want %s", lbl, want)
+	}
+}
+
+func TestSetKnownImportFileDoesNotLeakToParent(t *testing.T) {
+	parent := config.New()
+	path := writeTempFile(t, "overlay.csv", `example.com/foo.proto,@foo//:foo_proto,example.com/foo,proto_library
+`)
+	child := parent.Clone()
+	if err := setKnownImportFile(child, filepath.Dir(path), filepath.Base(path)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := findLabeledKind(getProtoConfig(child).knownImportOverlay["example.com/foo.proto"], "proto_library"); !ok {
+		t.Fatal("setKnownImportFile did not record the overlay on the config it was given")
+	}
+	if getProtoConfig(parent).knownImportOverlay != nil {
+		t.Error("setKnownImportFile on a cloned child config leaked its overlay back to the parent")
+	}
+}
+
+func TestSetKnownImportFileDoesNotLeakToSibling(t *testing.T) {
+	parent := config.New()
+	path := writeTempFile(t, "overlay.csv", `example.com/foo.proto,@foo//:foo_proto,example.com/foo,proto_library
+`)
+	childA := parent.Clone()
+	childB := parent.Clone()
+	if err := setKnownImportFile(childA, filepath.Dir(path), filepath.Base(path)); err != nil {
+		t.Fatal(err)
+	}
+
+	if getProtoConfig(childB).knownImportOverlay != nil {
+		t.Error("setKnownImportFile on one sibling config leaked its overlay to another sibling cloned from the same parent")
+	}
+}
+
+func TestResolveKnownImportPrefersOverrideOverGeneratedMap(t *testing.T) {
+	c := config.New()
+	idx := resolve.NewRuleIndex()
+
+	lbl, ok := resolveKnownImport(c, idx, "google/protobuf/any.proto", "proto_library")
+	if !ok || lbl.String() != "@com_google_protobuf//:any_proto" {
+		t.Fatalf("resolveKnownImport fell back to the generated map = %s, %v; want @com_google_protobuf//:any_proto, true", lbl, ok)
+	}
+
+	overrideLbl := label.New("", "custom", "any_proto")
+	resolve.SetOverride(c, "proto", "google/protobuf/any.proto", overrideLbl)
+	if lbl, ok := resolveKnownImport(c, idx, "google/protobuf/any.proto", "proto_library"); !ok || !lbl.Equal(overrideLbl) {
+		t.Errorf("resolveKnownImport = %s, %v; want override label %s once one was set", lbl, ok, overrideLbl)
+	}
+}
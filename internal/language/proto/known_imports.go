@@ -0,0 +1,87 @@
+// Generated by internal/language/proto/gen/gen_known_imports.go
+// From internal/language/proto/gen/proto.csv
+
+package proto
+
+import (
+	"github.com/bazelbuild/bazel-gazelle/internal/label"
+	"github.com/bazelbuild/bazel-gazelle/internal/resolve"
+)
+
+var knownProtoImports = map[string][]label.LabeledKind{
+
+	"google/protobuf/any.proto": {
+
+		{Label: label.New("com_google_protobuf", "", "any_proto"), Kind: "proto_library"},
+		{Label: label.New("io_bazel_rules_go", "proto/wkt", "any_go_proto"), Kind: "go_proto_library"},
+	},
+	"google/protobuf/duration.proto": {
+
+		{Label: label.New("com_google_protobuf", "", "duration_proto"), Kind: "proto_library"},
+		{Label: label.New("io_bazel_rules_go", "proto/wkt", "duration_go_proto"), Kind: "go_proto_library"},
+	},
+	"google/protobuf/timestamp.proto": {
+
+		{Label: label.New("com_google_protobuf", "", "timestamp_proto"), Kind: "proto_library"},
+		{Label: label.New("io_bazel_rules_go", "proto/wkt", "timestamp_go_proto"), Kind: "go_proto_library"},
+	},
+}
+
+var knownProtoImportsByLabel = map[label.Label]string{
+
+	label.New("com_google_protobuf", "", "any_proto"):                 "google/protobuf/any.proto",
+	label.New("io_bazel_rules_go", "proto/wkt", "any_go_proto"):       "google/protobuf/any.proto",
+	label.New("com_google_protobuf", "", "duration_proto"):            "google/protobuf/duration.proto",
+	label.New("io_bazel_rules_go", "proto/wkt", "duration_go_proto"):  "google/protobuf/duration.proto",
+	label.New("com_google_protobuf", "", "timestamp_proto"):           "google/protobuf/timestamp.proto",
+	label.New("io_bazel_rules_go", "proto/wkt", "timestamp_go_proto"): "google/protobuf/timestamp.proto",
+}
+
+// RegisterknownProtoImports seeds idx with knownProtoImports, so resolve.Resolve can find
+// these baked-in bindings without special-casing this map.
+func RegisterknownProtoImports(idx *resolve.RuleIndex) {
+	for imp, kinds := range knownProtoImports {
+		for _, lk := range kinds {
+			idx.AddStatic(resolve.ProtoLang, imp, lk.Kind, lk.Label)
+		}
+	}
+}
+
+var knownGoProtoImports = map[string][]label.LabeledKind{
+
+	"github.com/golang/protobuf/ptypes/any": {
+
+		{Label: label.New("com_google_protobuf", "", "any_proto"), Kind: "proto_library"},
+		{Label: label.New("io_bazel_rules_go", "proto/wkt", "any_go_proto"), Kind: "go_proto_library"},
+	},
+	"github.com/golang/protobuf/ptypes/duration": {
+
+		{Label: label.New("com_google_protobuf", "", "duration_proto"), Kind: "proto_library"},
+		{Label: label.New("io_bazel_rules_go", "proto/wkt", "duration_go_proto"), Kind: "go_proto_library"},
+	},
+	"github.com/golang/protobuf/ptypes/timestamp": {
+
+		{Label: label.New("com_google_protobuf", "", "timestamp_proto"), Kind: "proto_library"},
+		{Label: label.New("io_bazel_rules_go", "proto/wkt", "timestamp_go_proto"), Kind: "go_proto_library"},
+	},
+}
+
+var knownGoProtoImportsByLabel = map[label.Label]string{
+
+	label.New("com_google_protobuf", "", "any_proto"):                 "github.com/golang/protobuf/ptypes/any",
+	label.New("io_bazel_rules_go", "proto/wkt", "any_go_proto"):       "github.com/golang/protobuf/ptypes/any",
+	label.New("com_google_protobuf", "", "duration_proto"):            "github.com/golang/protobuf/ptypes/duration",
+	label.New("io_bazel_rules_go", "proto/wkt", "duration_go_proto"):  "github.com/golang/protobuf/ptypes/duration",
+	label.New("com_google_protobuf", "", "timestamp_proto"):           "github.com/golang/protobuf/ptypes/timestamp",
+	label.New("io_bazel_rules_go", "proto/wkt", "timestamp_go_proto"): "github.com/golang/protobuf/ptypes/timestamp",
+}
+
+// RegisterknownGoProtoImports seeds idx with knownGoProtoImports, so resolve.Resolve can find
+// these baked-in bindings without special-casing this map.
+func RegisterknownGoProtoImports(idx *resolve.RuleIndex) {
+	for imp, kinds := range knownGoProtoImports {
+		for _, lk := range kinds {
+			idx.AddStatic(resolve.GoLang, imp, lk.Kind, lk.Label)
+		}
+	}
+}
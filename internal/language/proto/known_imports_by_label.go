@@ -0,0 +1,32 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proto
+
+import "github.com/bazelbuild/bazel-gazelle/internal/label"
+
+// ImportPathByLabel returns the import that gen_known_imports.go recorded
+// for l, if any, without re-parsing proto.csv at runtime: its .proto import
+// from knownProtoImportsByLabel if that map has l, otherwise its Go import
+// from knownGoProtoImportsByLabel. A go_proto_library label that
+// knownProtoImportsByLabel also lists yields its .proto import, not its Go
+// import path.
+func ImportPathByLabel(l label.Label) (string, bool) {
+	if imp, ok := knownProtoImportsByLabel[l]; ok {
+		return imp, true
+	}
+	imp, ok := knownGoProtoImportsByLabel[l]
+	return imp, ok
+}
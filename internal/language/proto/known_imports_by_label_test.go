@@ -0,0 +1,45 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/bazelbuild/bazel-gazelle/internal/label"
+)
+
+func TestImportPathByLabelProto(t *testing.T) {
+	lbl := label.New("com_google_protobuf", "", "any_proto")
+	imp, ok := ImportPathByLabel(lbl)
+	if !ok || imp != "google/protobuf/any.proto" {
+		t.Errorf("ImportPathByLabel(%s) = %q, %v; want \"google/protobuf/any.proto\", true", lbl, imp, ok)
+	}
+}
+
+func TestImportPathByLabelGoProto(t *testing.T) {
+	lbl := label.New("io_bazel_rules_go", "proto/wkt", "any_go_proto")
+	imp, ok := ImportPathByLabel(lbl)
+	if !ok || imp != "google/protobuf/any.proto" {
+		t.Errorf("ImportPathByLabel(%s) = %q, %v; want \"google/protobuf/any.proto\", true", lbl, imp, ok)
+	}
+}
+
+func TestImportPathByLabelMiss(t *testing.T) {
+	lbl := label.New("", "unknown", "unknown_proto")
+	if _, ok := ImportPathByLabel(lbl); ok {
+		t.Errorf("ImportPathByLabel(%s) = _, true; want false for a label neither map knows about", lbl)
+	}
+}
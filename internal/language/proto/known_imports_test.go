@@ -0,0 +1,49 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/bazelbuild/bazel-gazelle/internal/resolve"
+)
+
+// TestRegisterknownGoProtoImportsUsesGoLang guards against the map/language
+// mismatch once silently reintroduced here: knownGoProtoImports is keyed by
+// Go import paths, so it must register under resolve.GoLang, not
+// resolve.ProtoLang like its proto-keyed sibling knownProtoImports.
+func TestRegisterknownGoProtoImportsUsesGoLang(t *testing.T) {
+	idx := resolve.NewRuleIndex()
+	RegisterknownGoProtoImports(idx)
+
+	const imp = "github.com/golang/protobuf/ptypes/any"
+	if _, ok := idx.Find(resolve.GoLang, imp, "go_proto_library"); !ok {
+		t.Errorf("Find(resolve.GoLang, %q, ...) = _, false; want a binding registered under GoLang", imp)
+	}
+	if _, ok := idx.Find(resolve.ProtoLang, imp, "go_proto_library"); ok {
+		t.Errorf("Find(resolve.ProtoLang, %q, ...) = _, true; knownGoProtoImports should not register under ProtoLang", imp)
+	}
+}
+
+func TestRegisterknownProtoImportsUsesProtoLang(t *testing.T) {
+	idx := resolve.NewRuleIndex()
+	RegisterknownProtoImports(idx)
+
+	const imp = "google/protobuf/any.proto"
+	if _, ok := idx.Find(resolve.ProtoLang, imp, "proto_library"); !ok {
+		t.Errorf("Find(resolve.ProtoLang, %q, ...) = _, false; want a binding registered under ProtoLang", imp)
+	}
+}
@@ -0,0 +1,149 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package resolve implements the cross-language rule index described in
+// bazel-contrib/rules_go#1046: a single table, built by walking every BUILD
+// file in the workspace, that each language consults before falling back to
+// its own static known-imports map.
+package resolve
+
+import (
+	"sync"
+
+	"github.com/bazelbuild/bazel-gazelle/internal/config"
+	"github.com/bazelbuild/bazel-gazelle/internal/label"
+	"github.com/bazelbuild/bazel-gazelle/internal/rule"
+)
+
+// Language identifies which language's import space a RuleIndex key belongs
+// to. Two languages can use the same string to mean different things (a Go
+// import path and a .proto file path can collide), so every lookup is
+// qualified by Language.
+type Language int
+
+const (
+	GoLang Language = iota
+	ProtoLang
+)
+
+func (l Language) String() string {
+	switch l {
+	case GoLang:
+		return "go"
+	case ProtoLang:
+		return "proto"
+	default:
+		return "unknown"
+	}
+}
+
+// languageAttrs maps each Language to the rule attribute that carries its
+// import string, following the convention rules_go and rules_proto already
+// use: go_library's importpath, proto_library's import.
+var languageAttrs = map[Language]string{
+	GoLang:    "importpath",
+	ProtoLang: "import",
+}
+
+// ruleKey qualifies an (Language, import) pair by the kind of rule asking
+// (e.g. "proto_library" vs. "go_proto_library"), since a single import can
+// legitimately resolve to a different label per kind — a .proto file backs
+// both a proto_library and a go_proto_library, and well-known types resolve
+// to different repos for each.
+type ruleKey struct {
+	Language Language
+	Import   string
+	Kind     string
+}
+
+// RuleIndex maps (Language, import string, rule kind) to the label of the
+// rule that provides it. It's built once per Gazelle invocation by walking
+// every BUILD file in the workspace, then updated incrementally as new
+// rules are generated during the same run, so a rule generated earlier in
+// the walk can resolve imports added later in the same invocation.
+//
+// Workspace entries (from AddFile, i.e. rules Gazelle actually found on
+// disk this run) and static entries (from a generated Register hook, i.e.
+// gen_known_imports.go's baked-in tables) are kept in separate maps rather
+// than merged into one: Find always prefers a workspace entry over a
+// static one for the same key, the same way the generator's own "seen" map
+// flags same-key conflicts instead of letting the last write win silently.
+type RuleIndex struct {
+	mu        sync.Mutex
+	workspace map[ruleKey]label.Label
+	static    map[ruleKey]label.Label
+}
+
+// NewRuleIndex returns an empty RuleIndex.
+func NewRuleIndex() *RuleIndex {
+	return &RuleIndex{
+		workspace: make(map[ruleKey]label.Label),
+		static:    make(map[ruleKey]label.Label),
+	}
+}
+
+// AddStatic records that lbl provides imp for lang when a rule of the
+// given kind asks, as a baked-in binding rather than one found on disk
+// this run. Each language's generated Register{{.Var}} hook calls this to
+// seed the index with gen_known_imports.go's tables.
+func (idx *RuleIndex) AddStatic(lang Language, imp, kind string, lbl label.Label) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.static[ruleKey{lang, imp, kind}] = lbl
+}
+
+// AddFile indexes every rule in f that sets an import-bearing attribute for
+// one of languageAttrs, keyed by the rule's own kind (e.g. "go_library").
+// These are workspace entries: they take priority over any static entry
+// for the same key.
+func (idx *RuleIndex) AddFile(f *rule.File) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, r := range f.Rules {
+		for lang, attr := range languageAttrs {
+			imp := r.AttrString(attr)
+			if imp == "" {
+				continue
+			}
+			idx.workspace[ruleKey{lang, imp, r.Kind()}] = label.New("", f.Pkg, r.Name())
+		}
+	}
+}
+
+// Find looks up imp for lang as requested by a rule of the given kind,
+// preferring a workspace entry over a static one. It returns ok=false if
+// neither map provides it under that kind.
+func (idx *RuleIndex) Find(lang Language, imp, kind string) (label.Label, bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	key := ruleKey{lang, imp, kind}
+	if lbl, ok := idx.workspace[key]; ok {
+		return lbl, true
+	}
+	lbl, ok := idx.static[key]
+	return lbl, ok
+}
+
+// Resolve resolves imp for lang, as requested by a rule of the given kind,
+// in Gazelle's standard order: an explicit `# gazelle:resolve` override
+// wins if present, then idx's workspace entries (this invocation's own
+// BUILD files), and finally idx's static entries (the
+// knownProtoImports/knownGoImports tables from gen_known_imports.go).
+func Resolve(c *config.Config, idx *RuleIndex, lang Language, imp, kind string) (label.Label, bool) {
+	if lbl, ok := FindRuleWithOverride(c, imp, lang.String()); ok {
+		return lbl, true
+	}
+	return idx.Find(lang, imp, kind)
+}
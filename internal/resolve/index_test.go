@@ -0,0 +1,134 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolve
+
+import (
+	"testing"
+
+	"github.com/bazelbuild/bazel-gazelle/internal/config"
+	"github.com/bazelbuild/bazel-gazelle/internal/label"
+	"github.com/bazelbuild/bazel-gazelle/internal/rule"
+)
+
+func TestApplyResolveDirectivesSetsOverride(t *testing.T) {
+	c := config.New()
+	directives := []rule.Directive{
+		{Key: "resolve", Value: "go example.com/foo @foo//:go_default_library"},
+	}
+	if err := ApplyResolveDirectives(c, directives); err != nil {
+		t.Fatal(err)
+	}
+	wantLbl := label.New("foo", "", "go_default_library")
+	lbl, ok := FindRuleWithOverride(c, "example.com/foo", "go")
+	if !ok || !lbl.Equal(wantLbl) {
+		t.Errorf("FindRuleWithOverride = %s, %v; want %s, true", lbl, ok, wantLbl)
+	}
+}
+
+func TestApplyResolveDirectivesRejectsMalformedValue(t *testing.T) {
+	c := config.New()
+	directives := []rule.Directive{
+		{Key: "resolve", Value: "go example.com/foo"},
+	}
+	if err := ApplyResolveDirectives(c, directives); err == nil {
+		t.Error("ApplyResolveDirectives accepted a directive value missing its label field")
+	}
+}
+
+func TestFindPrefersWorkspaceOverStatic(t *testing.T) {
+	idx := NewRuleIndex()
+	staticLbl := label.New("com_google_protobuf", "", "any_proto")
+	idx.AddStatic(ProtoLang, "google/protobuf/any.proto", "proto_library", staticLbl)
+
+	if _, ok := idx.Find(ProtoLang, "google/protobuf/any.proto", "proto_library"); !ok {
+		t.Fatal("Find did not return the static entry before any workspace entry was added")
+	}
+
+	r := rule.NewRule("proto_library", "any_proto")
+	r.SetAttr("import", "google/protobuf/any.proto")
+	f := &rule.File{Pkg: "vendor/any", Rules: []*rule.Rule{r}}
+	idx.AddFile(f)
+
+	lbl, ok := idx.Find(ProtoLang, "google/protobuf/any.proto", "proto_library")
+	if !ok {
+		t.Fatal("Find returned ok=false after AddFile indexed a matching rule")
+	}
+	wantLbl := label.New("", "vendor/any", "any_proto")
+	if !lbl.Equal(wantLbl) {
+		t.Errorf("Find returned %s, want workspace label %s (static entry %s should have been shadowed)", lbl, wantLbl, staticLbl)
+	}
+}
+
+func TestFindFallsBackToStatic(t *testing.T) {
+	idx := NewRuleIndex()
+	wantLbl := label.New("com_google_protobuf", "", "any_proto")
+	idx.AddStatic(ProtoLang, "google/protobuf/any.proto", "proto_library", wantLbl)
+
+	lbl, ok := idx.Find(ProtoLang, "google/protobuf/any.proto", "proto_library")
+	if !ok {
+		t.Fatal("Find returned ok=false for an import only present in the static map")
+	}
+	if !lbl.Equal(wantLbl) {
+		t.Errorf("Find returned %s, want %s", lbl, wantLbl)
+	}
+}
+
+func TestFindDistinguishesKind(t *testing.T) {
+	idx := NewRuleIndex()
+	protoLbl := label.New("com_google_protobuf", "", "any_proto")
+	goProtoLbl := label.New("io_bazel_rules_go", "proto/wkt", "any_go_proto")
+	idx.AddStatic(ProtoLang, "google/protobuf/any.proto", "proto_library", protoLbl)
+	idx.AddStatic(ProtoLang, "google/protobuf/any.proto", "go_proto_library", goProtoLbl)
+
+	if lbl, ok := idx.Find(ProtoLang, "google/protobuf/any.proto", "proto_library"); !ok || !lbl.Equal(protoLbl) {
+		t.Errorf("Find(..., %q) = %s, %v; want %s, true", "proto_library", lbl, ok, protoLbl)
+	}
+	if lbl, ok := idx.Find(ProtoLang, "google/protobuf/any.proto", "go_proto_library"); !ok || !lbl.Equal(goProtoLbl) {
+		t.Errorf("Find(..., %q) = %s, %v; want %s, true", "go_proto_library", lbl, ok, goProtoLbl)
+	}
+}
+
+func TestResolvePrefersOverride(t *testing.T) {
+	c := config.New()
+	overrideLbl := label.New("", "custom", "any_proto")
+	SetOverride(c, "proto", "google/protobuf/any.proto", overrideLbl)
+
+	idx := NewRuleIndex()
+	idx.AddStatic(ProtoLang, "google/protobuf/any.proto", "proto_library", label.New("com_google_protobuf", "", "any_proto"))
+
+	lbl, ok := Resolve(c, idx, ProtoLang, "google/protobuf/any.proto", "proto_library")
+	if !ok {
+		t.Fatal("Resolve returned ok=false with both an override and a static entry present")
+	}
+	if !lbl.Equal(overrideLbl) {
+		t.Errorf("Resolve returned %s, want override label %s", lbl, overrideLbl)
+	}
+}
+
+func TestResolveFallsBackToIndex(t *testing.T) {
+	c := config.New()
+	idx := NewRuleIndex()
+	wantLbl := label.New("com_google_protobuf", "", "any_proto")
+	idx.AddStatic(ProtoLang, "google/protobuf/any.proto", "proto_library", wantLbl)
+
+	lbl, ok := Resolve(c, idx, ProtoLang, "google/protobuf/any.proto", "proto_library")
+	if !ok {
+		t.Fatal("Resolve returned ok=false with no override configured")
+	}
+	if !lbl.Equal(wantLbl) {
+		t.Errorf("Resolve returned %s, want %s", lbl, wantLbl)
+	}
+}
@@ -0,0 +1,96 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolve
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bazelbuild/bazel-gazelle/internal/config"
+	"github.com/bazelbuild/bazel-gazelle/internal/label"
+	"github.com/bazelbuild/bazel-gazelle/internal/rule"
+)
+
+// resolveDirective is the name of the `# gazelle:resolve` directive: a
+// per-(lang, import) override that always wins over whatever the RuleIndex
+// or a language's static known-imports table would otherwise pick.
+const resolveDirective = "resolve"
+
+type overrideKey struct {
+	Lang, Import string
+}
+
+// overrideConfig holds resolve overrides accumulated from directives, one
+// per (Config, directory) the same way protoConfig holds its own
+// directory-scoped state.
+type overrideConfig struct {
+	overrides map[overrideKey]label.Label
+}
+
+func getOverrideConfig(c *config.Config) *overrideConfig {
+	oc, ok := c.Exts[resolveDirective].(*overrideConfig)
+	if !ok {
+		oc = &overrideConfig{}
+	}
+	return oc
+}
+
+// SetOverride records that imp should always resolve to lbl for lang,
+// overwriting any override inherited from an ancestor directory. Like
+// protoConfig, c's existing *overrideConfig is shared with its parent and
+// siblings, so it's cloned before the new override is added.
+func SetOverride(c *config.Config, lang, imp string, lbl label.Label) {
+	ocCopy := *getOverrideConfig(c)
+	overrides := make(map[overrideKey]label.Label, len(ocCopy.overrides)+1)
+	for k, v := range ocCopy.overrides {
+		overrides[k] = v
+	}
+	overrides[overrideKey{lang, imp}] = lbl
+	ocCopy.overrides = overrides
+	c.Exts[resolveDirective] = &ocCopy
+}
+
+// FindRuleWithOverride looks up an explicit `# gazelle:resolve` override
+// for imp in lang's import space, returning ok=false if none was
+// configured.
+func FindRuleWithOverride(c *config.Config, imp string, lang string) (label.Label, bool) {
+	oc := getOverrideConfig(c)
+	lbl, ok := oc.overrides[overrideKey{lang, imp}]
+	return lbl, ok
+}
+
+// ApplyResolveDirectives scans directives for resolveDirective, each of the
+// form `# gazelle:resolve lang import label`, and records the override it
+// describes. Configure should call this for every BUILD file it visits, the
+// same way proto.applyKnownImportFileDirectives handles its own directive.
+func ApplyResolveDirectives(c *config.Config, directives []rule.Directive) error {
+	for _, d := range directives {
+		if d.Key != resolveDirective {
+			continue
+		}
+		fields := strings.Fields(d.Value)
+		if len(fields) != 3 {
+			return fmt.Errorf("%s: expected \"lang import label\", got %q", resolveDirective, d.Value)
+		}
+		lang, imp, labelStr := fields[0], fields[1], fields[2]
+		lbl, err := label.Parse(labelStr)
+		if err != nil {
+			return fmt.Errorf("%s: %v", resolveDirective, err)
+		}
+		SetOverride(c, lang, imp, lbl)
+	}
+	return nil
+}
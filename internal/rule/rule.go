@@ -0,0 +1,67 @@
+/* Copyright 2017 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rule provides a representation of Bazel BUILD files and the
+// rules in them, independent of any particular language's generation
+// logic.
+package rule
+
+// Directive represents a `# gazelle:key value` comment in a BUILD file.
+type Directive struct {
+	Key   string
+	Value string
+}
+
+// Rule represents a single rule instance in a BUILD file, e.g. a
+// go_library or proto_library target.
+type Rule struct {
+	kind, name string
+	attrs      map[string]string
+}
+
+// NewRule returns a Rule of the given kind and name with no attributes
+// set.
+func NewRule(kind, name string) *Rule {
+	return &Rule{kind: kind, name: name, attrs: make(map[string]string)}
+}
+
+// Kind returns the rule's kind, e.g. "go_library".
+func (r *Rule) Kind() string { return r.kind }
+
+// Name returns the rule's name, i.e. the value that follows the rule
+// kind's colon in its label.
+func (r *Rule) Name() string { return r.name }
+
+// SetAttr sets a string-valued attribute on the rule, such as importpath
+// or import.
+func (r *Rule) SetAttr(key, value string) { r.attrs[key] = value }
+
+// AttrString returns the string value of the named attribute, or "" if
+// it's not set.
+func (r *Rule) AttrString(key string) string { return r.attrs[key] }
+
+// File represents a BUILD file: the package it's in, the directives set
+// in its comments, and the rules it declares.
+type File struct {
+	// Pkg is the slash-separated path to the package this file declares,
+	// relative to the repository root.
+	Pkg string
+
+	// Directives holds every `# gazelle:` directive found in the file.
+	Directives []Directive
+
+	// Rules holds every rule declared in the file.
+	Rules []*Rule
+}